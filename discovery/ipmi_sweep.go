@@ -0,0 +1,211 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ipmiRMCPPort     = 623
+	sweepPingTimeout = 300 * time.Millisecond
+)
+
+// vendorByOEMID maps the IANA Private Enterprise Number a BMC reports in its
+// "Get Channel Authentication Capabilities" response to a friendly vendor
+// name, for the vendors we actually operate.
+var vendorByOEMID = map[uint32]string{
+	674:   "Dell",
+	11:    "HPE",
+	10876: "Supermicro",
+}
+
+// IPMISweepSource discovers BMCs by sending an IPMI RMCP presence ping (Get
+// Channel Authentication Capabilities) to every address in a set of CIDRs
+// and recording which ones answer. It runs on a periodic interval since,
+// unlike mDNS, there's no push notification for "a BMC appeared."
+type IPMISweepSource struct {
+	cidrs    []string
+	interval time.Duration
+}
+
+func NewIPMISweepSource(cidrs []string, interval time.Duration) *IPMISweepSource {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &IPMISweepSource{cidrs: cidrs, interval: interval}
+}
+
+func (s *IPMISweepSource) Name() string { return "ipmi-sweep" }
+
+func (s *IPMISweepSource) Run(ctx context.Context, onUpdate func(servers map[string]*Server)) {
+	s.sweep(ctx, onUpdate)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx, onUpdate)
+		}
+	}
+}
+
+func (s *IPMISweepSource) sweep(ctx context.Context, onUpdate func(servers map[string]*Server)) {
+	var ips []net.IP
+	for _, cidr := range s.cidrs {
+		hosts, err := expandCIDR(cidr)
+		if err != nil {
+			log.Warnf("ipmi-sweep: skipping invalid CIDR %q: %v", cidr, err)
+			continue
+		}
+		ips = append(ips, hosts...)
+	}
+	if len(ips) == 0 {
+		return
+	}
+
+	results := make(chan *Server, len(ips))
+	sem := make(chan struct{}, 64) // bound concurrent sockets
+	var wg sync.WaitGroup
+
+	for _, ip := range ips {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		ip := ip
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if srv := pingIPMI(ip); srv != nil {
+				results <- srv
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	servers := make(map[string]*Server)
+	for srv := range results {
+		servers[srv.Hostname] = srv
+	}
+	onUpdate(servers)
+}
+
+// pingIPMI sends a Get Channel Authentication Capabilities request and, if
+// the address answers like a BMC, returns a Server named after its IP (the
+// caller can rename it later once paired with pxemanager/DNS data).
+func pingIPMI(ip net.IP) *Server {
+	raddr := &net.UDPAddr{IP: ip, Port: ipmiRMCPPort}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	req := buildAuthCapabilitiesRequest()
+	conn.SetDeadline(time.Now().Add(sweepPingTimeout))
+	if _, err := conn.Write(req); err != nil {
+		return nil
+	}
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil || n < 20 {
+		return nil
+	}
+
+	srv := &Server{IP: ip.String(), Hostname: ip.String(), Online: true}
+	if oemID, ok := parseOEMID(buf[:n]); ok {
+		if vendor, known := vendorByOEMID[oemID]; known {
+			srv.Hostname = fmt.Sprintf("%s-%s", vendor, ip.String())
+		}
+	}
+	return srv
+}
+
+const (
+	cmdGetChannelAuthCapabilities byte = 0x38
+	authCapChannelCurrent         byte = 0x0e // "this channel"
+	authCapPrivLevelAdmin         byte = 0x04
+)
+
+func buildAuthCapabilitiesRequest() []byte {
+	// RMCP header + IPMI session header (auth type none, session seq/id 0)
+	// + request: NetFn App (0x06), cmd Get Channel Auth Capabilities (0x38).
+	header := []byte{0x06, 0x00, 0xff, 0x07}
+	session := []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	rsAddr, netFnLUN := byte(0x20), byte(0x06<<2)
+	checksum1 := ipmiChecksum([]byte{rsAddr, netFnLUN})
+	rqAddr, rqSeqLUN := byte(0x81), byte(0x00)
+	data := []byte{authCapChannelCurrent, authCapPrivLevelAdmin}
+	checksum2 := ipmiChecksum(append([]byte{rqAddr, rqSeqLUN, cmdGetChannelAuthCapabilities}, data...))
+
+	req := []byte{rsAddr, netFnLUN, checksum1, rqAddr, rqSeqLUN, cmdGetChannelAuthCapabilities}
+	req = append(req, data...)
+	req = append(req, checksum2)
+
+	pkt := append(header, session...)
+	pkt = append(pkt, byte(len(req)))
+	pkt = append(pkt, req...)
+	return pkt
+}
+
+func ipmiChecksum(b []byte) byte {
+	var sum byte
+	for _, v := range b {
+		sum += v
+	}
+	return -sum
+}
+
+// parseOEMID extracts the manufacturer ID field from a Get Channel Auth
+// Capabilities response. The response's command-specific data ends with
+// oem_id[3] followed by oem_aux and the message's trailing checksum2, so
+// the OEM ID is the 3 bytes starting 5 bytes before the end of the packet,
+// not the last 3 bytes.
+func parseOEMID(resp []byte) (uint32, bool) {
+	if len(resp) < 20 {
+		return 0, false
+	}
+	oemBytes := resp[len(resp)-5 : len(resp)-2]
+	return uint32(oemBytes[0]) | uint32(oemBytes[1])<<8 | uint32(oemBytes[2])<<16, true
+}
+
+func expandCIDR(cidr string) ([]net.IP, error) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	base := binary.BigEndian.Uint32(ip.To4())
+	mask := binary.BigEndian.Uint32(ipNet.Mask)
+	network := base & mask
+	broadcast := network | ^mask
+
+	// Skip network and broadcast addresses for typical /24-or-larger ranges.
+	for v := network + 1; v < broadcast; v++ {
+		addr := make(net.IP, 4)
+		binary.BigEndian.PutUint32(addr, v)
+		ips = append(ips, addr)
+	}
+	return ips, nil
+}
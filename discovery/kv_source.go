@@ -0,0 +1,121 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// KVStore is the minimal read interface KVSource needs from a KV backend.
+// Consul's *api.KV and etcd's clientv3.KV are both adapted to this via
+// ConsulKVStore/EtcdKVStore below, so KVSource itself stays backend-agnostic.
+type KVStore interface {
+	// List returns every key/value pair under prefix, keyed by the full key.
+	List(ctx context.Context, prefix string) (map[string][]byte, error)
+}
+
+// KVSource polls a KV store (Consul or etcd) for server records stored as
+// JSON values under a key prefix, one key per server
+// (e.g. "discovery/servers/web-01" -> {"ip": "10.0.0.5", ...}).
+type KVSource struct {
+	name     string
+	store    KVStore
+	prefix   string
+	interval time.Duration
+}
+
+func NewKVSource(name string, store KVStore, prefix string, interval time.Duration) *KVSource {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &KVSource{name: name, store: store, prefix: prefix, interval: interval}
+}
+
+func (s *KVSource) Name() string { return s.name }
+
+func (s *KVSource) Run(ctx context.Context, onUpdate func(servers map[string]*Server)) {
+	s.poll(ctx, onUpdate)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx, onUpdate)
+		}
+	}
+}
+
+func (s *KVSource) poll(ctx context.Context, onUpdate func(servers map[string]*Server)) {
+	kvs, err := s.store.List(ctx, s.prefix)
+	if err != nil {
+		log.Warnf("%s: failed to list KV prefix %s: %v", s.name, s.prefix, err)
+		return
+	}
+
+	servers := make(map[string]*Server, len(kvs))
+	for key, value := range kvs {
+		var entry StaticFileEntry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			log.Warnf("%s: failed to parse value for key %s: %v", s.name, key, err)
+			continue
+		}
+		if entry.Name == "" {
+			entry.Name = path.Base(key)
+		}
+		if entry.IP == "" {
+			continue
+		}
+		servers[entry.Name] = &Server{IP: entry.IP, Hostname: entry.Name, MAC: entry.MAC, Online: true}
+	}
+
+	onUpdate(servers)
+}
+
+// ConsulKVStore adapts a real *consulapi.Client (github.com/hashicorp/consul/api)
+// to KVStore.
+type ConsulKVStore struct {
+	Client *consulapi.Client
+}
+
+func (c *ConsulKVStore) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	opts := (&consulapi.QueryOptions{}).WithContext(ctx)
+	pairs, _, err := c.Client.KV().List(prefix, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]byte, len(pairs))
+	for _, p := range pairs {
+		result[p.Key] = p.Value
+	}
+	return result, nil
+}
+
+// EtcdKVStore adapts a real clientv3.KV (go.etcd.io/etcd/client/v3) to
+// KVStore.
+type EtcdKVStore struct {
+	Client clientv3.KV
+}
+
+func (e *EtcdKVStore) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	resp, err := e.Client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]byte, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		result[string(kv.Key)] = kv.Value
+	}
+	return result, nil
+}
@@ -0,0 +1,200 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var mdnsServiceNames = []string{"_ipmi._udp.local.", "_redfish._tcp.local."}
+
+const (
+	mdnsMulticastAddr = "224.0.0.251:5353"
+	mdnsQueryInterval = time.Minute
+)
+
+// MDNSSource listens for mDNS/DNS-SD announcements of _ipmi._udp and
+// _redfish._tcp services, for BMCs that advertise themselves instead of (or
+// in addition to) being known to pxemanager.
+type MDNSSource struct{}
+
+func NewMDNSSource() *MDNSSource { return &MDNSSource{} }
+
+func (s *MDNSSource) Name() string { return "mdns" }
+
+func (s *MDNSSource) Run(ctx context.Context, onUpdate func(servers map[string]*Server)) {
+	addr, err := net.ResolveUDPAddr("udp4", mdnsMulticastAddr)
+	if err != nil {
+		log.Warnf("mdns: resolve multicast addr: %v", err)
+		return
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", nil, addr)
+	if err != nil {
+		log.Warnf("mdns: listen on %s: %v", mdnsMulticastAddr, err)
+		return
+	}
+	defer conn.Close()
+
+	var mu sync.Mutex
+	servers := make(map[string]*Server)
+
+	go s.queryPeriodically(ctx, addr)
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, 9000)
+	for {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				continue
+			}
+		}
+
+		srv := parseMDNSResponse(buf[:n], from)
+		if srv == nil {
+			continue
+		}
+
+		mu.Lock()
+		changed := servers[srv.Hostname] == nil || *servers[srv.Hostname] != *srv
+		if changed {
+			servers[srv.Hostname] = srv
+		}
+		snapshot := make(map[string]*Server, len(servers))
+		for k, v := range servers {
+			snapshot[k] = v
+		}
+		mu.Unlock()
+
+		if changed {
+			onUpdate(snapshot)
+		}
+	}
+}
+
+// queryPeriodically re-sends the service queries so devices that only
+// respond to a direct query (rather than announcing on their own schedule)
+// are still discovered.
+func (s *MDNSSource) queryPeriodically(ctx context.Context, addr *net.UDPAddr) {
+	conn, err := net.DialUDP("udp4", nil, addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	send := func() {
+		for _, name := range mdnsServiceNames {
+			msg, err := buildMDNSQuery(name)
+			if err != nil {
+				continue
+			}
+			conn.Write(msg)
+		}
+	}
+
+	send()
+	ticker := time.NewTicker(mdnsQueryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+func buildMDNSQuery(name string) ([]byte, error) {
+	var b dnsmessage.Builder
+	b.StartQuestions()
+	err := b.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName(name),
+		Type:  dnsmessage.TypePTR,
+		Class: dnsmessage.ClassINET,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// parseMDNSResponse looks for a PTR record for one of our watched service
+// names and returns the corresponding Server, if any. The IP comes from an A
+// record in the same response, or from the sender's address if there is
+// none; AAAA records are not read.
+func parseMDNSResponse(data []byte, from *net.UDPAddr) *Server {
+	var parser dnsmessage.Parser
+	if _, err := parser.Start(data); err != nil {
+		return nil
+	}
+	parser.SkipAllQuestions()
+
+	var hostname string
+	var ip net.IP
+
+	for {
+		header, err := parser.AnswerHeader()
+		if err != nil {
+			break
+		}
+
+		switch header.Type {
+		case dnsmessage.TypePTR:
+			watched := false
+			for _, name := range mdnsServiceNames {
+				if strings.EqualFold(header.Name.String(), name) {
+					watched = true
+				}
+			}
+			if !watched {
+				parser.SkipAnswer()
+				break
+			}
+			ptr, err := parser.PTRResource()
+			if err == nil {
+				hostname = strings.TrimSuffix(ptr.PTR.String(), ".")
+			} else {
+				parser.SkipAnswer()
+			}
+		case dnsmessage.TypeA:
+			a, err := parser.AResource()
+			if err == nil {
+				ip = net.IP(a.A[:])
+			} else {
+				parser.SkipAnswer()
+			}
+		default:
+			parser.SkipAnswer()
+		}
+	}
+
+	if ip == nil {
+		ip = from.IP
+	}
+	if hostname == "" {
+		return nil
+	}
+
+	return &Server{
+		IP:       ip.String(),
+		Hostname: strings.TrimSuffix(hostname, "."),
+		Online:   true,
+	}
+}
+
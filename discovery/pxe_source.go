@@ -0,0 +1,120 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PXEHost represents a host from the pxemanager API
+type PXEHost struct {
+	ID           int64  `json:"id"`
+	MAC          string `json:"mac"`
+	Hostname     string `json:"hostname"`
+	CurrentImage string `json:"current_image"`
+	IPMIIP       string `json:"ipmi_ip"`
+	IPMIUsername string `json:"ipmi_username"`
+	IPMIPassword string `json:"ipmi_password"`
+}
+
+// PXESource polls the pxemanager HTTP API for hosts, the original (and
+// still primary) way servers are discovered.
+type PXESource struct {
+	pxeURL     string
+	httpClient *http.Client
+	interval   time.Duration
+
+	mu       sync.Mutex
+	onUpdate func(servers map[string]*Server)
+}
+
+func NewPXESource(pxeURL string) *PXESource {
+	return &PXESource{
+		pxeURL:     pxeURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		interval:   30 * time.Second,
+	}
+}
+
+func (s *PXESource) Name() string { return "pxemanager" }
+
+func (s *PXESource) Run(ctx context.Context, onUpdate func(servers map[string]*Server)) {
+	if s.pxeURL == "" {
+		return
+	}
+
+	s.mu.Lock()
+	s.onUpdate = onUpdate
+	s.mu.Unlock()
+
+	s.poll(onUpdate)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(onUpdate)
+		}
+	}
+}
+
+func (s *PXESource) poll(onUpdate func(servers map[string]*Server)) {
+	resp, err := s.httpClient.Get(s.pxeURL + "/api/hosts")
+	if err != nil {
+		log.Warnf("Failed to fetch hosts from pxemanager: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var hosts []PXEHost
+	if err := json.NewDecoder(resp.Body).Decode(&hosts); err != nil {
+		log.Warnf("Failed to decode pxemanager response: %v", err)
+		return
+	}
+
+	servers := make(map[string]*Server)
+	for _, h := range hosts {
+		if h.IPMIIP == "" {
+			continue
+		}
+
+		name := h.Hostname
+		if name == "" {
+			name = h.IPMIIP
+		}
+		if idx := strings.Index(name, "."); idx > 0 && net.ParseIP(name) == nil {
+			name = name[:idx]
+		}
+
+		servers[name] = &Server{
+			IP:       h.IPMIIP,
+			Hostname: name,
+			Online:   true,
+			MAC:      h.MAC,
+		}
+	}
+
+	onUpdate(servers)
+}
+
+// Refresh triggers an immediate poll, outside the normal interval, for
+// callers that want to force a resync (e.g. after a known pxemanager change).
+func (s *PXESource) Refresh() {
+	s.mu.Lock()
+	onUpdate := s.onUpdate
+	s.mu.Unlock()
+
+	if onUpdate != nil {
+		s.poll(onUpdate)
+	}
+}
@@ -2,12 +2,8 @@ package discovery
 
 import (
 	"context"
-	"encoding/json"
 	"net"
-	"net/http"
-	"strings"
 	"sync"
-	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -19,53 +15,97 @@ type Server struct {
 	MAC      string
 }
 
-// PXEHost represents a host from the pxemanager API
-type PXEHost struct {
-	ID           int64  `json:"id"`
-	MAC          string `json:"mac"`
-	Hostname     string `json:"hostname"`
-	CurrentImage string `json:"current_image"`
-	IPMIIP       string `json:"ipmi_ip"`
-	IPMIUsername string `json:"ipmi_username"`
-	IPMIPassword string `json:"ipmi_password"`
+// ChangeSet describes what changed in the merged view of servers after a
+// source update, so downstream code can react incrementally instead of
+// diffing the full map itself.
+type ChangeSet struct {
+	Added   map[string]*Server
+	Removed map[string]*Server
+	Changed map[string]*Server
 }
 
+func (c ChangeSet) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// manualSourceName is the pseudo-source AddServer writes into, so manually
+// added servers participate in priority merging like any other source.
+const manualSourceName = "manual"
+
+type sourceEntry struct {
+	source   DiscoverySource
+	priority int
+}
+
+// Scanner merges server records from any number of DiscoverySources
+// (pxemanager, IPMI ping sweep, mDNS, a static file, Consul/etcd, ...),
+// resolving conflicts by source priority, and emits Added/Removed/Changed
+// deltas as sources report updates.
 type Scanner struct {
-	servers    map[string]*Server
-	mu         sync.RWMutex
-	onChange   func(servers map[string]*Server)
-	pxeURL     string
-	httpClient *http.Client
+	mu       sync.RWMutex
+	sources  []sourceEntry
+	fromEach map[string]map[string]*Server // source name -> its current view
+	merged   map[string]*Server            // resolved view across all sources
+	onChange func(ChangeSet)
+
+	// pxeURL preserves the simple single-source constructor used before
+	// multiple sources existed.
+	pxeURL string
 }
 
+// NewScanner preserves the original single-source (pxemanager) constructor.
+// Use AddSource to register additional discovery sources.
 func NewScanner(pxeURL string) *Scanner {
-	return &Scanner{
-		servers:    make(map[string]*Server),
-		pxeURL:     pxeURL,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+	s := &Scanner{
+		fromEach: make(map[string]map[string]*Server),
+		merged:   make(map[string]*Server),
+		pxeURL:   pxeURL,
 	}
+	s.AddSource(NewPXESource(pxeURL), 0)
+	return s
 }
 
-func (s *Scanner) AddServer(name, host string) {
+// AddSource registers a discovery source. Lower priority values win conflicts
+// (0 is highest priority); ties are broken by registration order. Must be
+// called before Run.
+func (s *Scanner) AddSource(source DiscoverySource, priority int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.sources = append(s.sources, sourceEntry{source: source, priority: priority})
+}
 
-	// Resolve hostname to IP
+// AddServer manually registers a server, resolving its IP from a hostname if
+// needed. It participates in merging as the lowest-priority "manual" source,
+// so an automatically-discovered record for the same name wins if one shows
+// up with higher priority.
+func (s *Scanner) AddServer(name, host string) {
 	ip := host
 	if addrs, err := net.LookupHost(host); err == nil && len(addrs) > 0 {
 		ip = addrs[0]
 	}
 
-	s.servers[name] = &Server{
-		IP:       ip,
-		Hostname: name,
-		Online:   true,
+	s.mu.Lock()
+	existing := s.fromEach[manualSourceName]
+	if existing == nil {
+		existing = make(map[string]*Server)
+	} else {
+		// copy so we don't mutate a map callers may have taken a reference to
+		copied := make(map[string]*Server, len(existing))
+		for k, v := range existing {
+			copied[k] = v
+		}
+		existing = copied
 	}
+	existing[name] = &Server{IP: ip, Hostname: name, Online: true}
+	s.mu.Unlock()
 
 	log.Infof("Added server: %s (%s -> %s)", name, host, ip)
+	s.applyUpdate(manualSourceName, existing)
 }
 
-func (s *Scanner) OnChange(fn func(servers map[string]*Server)) {
+func (s *Scanner) OnChange(fn func(ChangeSet)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.onChange = fn
 }
 
@@ -73,110 +113,139 @@ func (s *Scanner) GetServers() map[string]*Server {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	result := make(map[string]*Server)
-	for k, v := range s.servers {
+	result := make(map[string]*Server, len(s.merged))
+	for k, v := range s.merged {
 		result[k] = v
 	}
 	return result
 }
 
-// Refresh triggers an immediate fetch from pxemanager
+// Refresh triggers an immediate poll on any registered source that supports
+// on-demand refresh (currently PXESource); push-based sources (mDNS, file
+// watch) are already current, and the other polling sources (IPMI sweep, KV)
+// pick up changes on their next interval.
 func (s *Scanner) Refresh() {
-	s.fetchFromPXE()
+	s.mu.RLock()
+	sources := make([]DiscoverySource, len(s.sources))
+	for i, e := range s.sources {
+		sources[i] = e.source
+	}
+	s.mu.RUnlock()
+
+	for _, src := range sources {
+		if r, ok := src.(interface{ Refresh() }); ok {
+			r.Refresh()
+		}
+	}
 }
 
+// Run starts every registered source and merges their updates until ctx is
+// cancelled.
 func (s *Scanner) Run(ctx context.Context) {
-	// Initial fetch from pxemanager
-	s.fetchFromPXE()
-
-	// Trigger initial onChange
-	if s.onChange != nil {
-		s.onChange(s.GetServers())
+	s.mu.RLock()
+	sources := make([]sourceEntry, len(s.sources))
+	copy(sources, s.sources)
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, entry := range sources {
+		entry := entry
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry.source.Run(ctx, func(servers map[string]*Server) {
+				s.applyUpdate(entry.source.Name(), servers)
+			})
+		}()
 	}
 
-	// Periodic refresh from pxemanager
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+	wg.Wait()
+}
+
+// applyUpdate records a source's latest view, recomputes the merged view by
+// priority, and emits a ChangeSet if anything changed.
+func (s *Scanner) applyUpdate(sourceName string, servers map[string]*Server) {
+	s.mu.Lock()
+
+	s.fromEach[sourceName] = servers
+	newMerged := s.resolveLocked()
+	changes := diffServers(s.merged, newMerged)
+	s.merged = newMerged
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			s.fetchFromPXE()
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	if !changes.Empty() {
+		log.Infof("Discovery update from %s: +%d -%d ~%d", sourceName, len(changes.Added), len(changes.Removed), len(changes.Changed))
+		if onChange != nil {
+			onChange(changes)
 		}
 	}
 }
 
-func (s *Scanner) fetchFromPXE() {
-	if s.pxeURL == "" {
-		return
+// resolveLocked merges every source's current view by priority (lowest
+// number wins); callers must hold s.mu.
+func (s *Scanner) resolveLocked() map[string]*Server {
+	ordered := make([]sourceEntry, len(s.sources))
+	copy(ordered, s.sources)
+	// Stable-sort ascending by priority so the first source to claim a name
+	// wins and later (lower-priority) sources only fill gaps.
+	for i := 1; i < len(ordered); i++ {
+		for j := i; j > 0 && ordered[j].priority < ordered[j-1].priority; j-- {
+			ordered[j], ordered[j-1] = ordered[j-1], ordered[j]
+		}
 	}
 
-	resp, err := s.httpClient.Get(s.pxeURL + "/api/hosts")
-	if err != nil {
-		log.Warnf("Failed to fetch hosts from pxemanager: %v", err)
-		return
+	merged := make(map[string]*Server)
+	claimed := make(map[string]bool)
+
+	applyOne := func(name string, view map[string]*Server) {
+		for serverName, srv := range view {
+			if claimed[serverName] {
+				continue
+			}
+			merged[serverName] = srv
+		}
 	}
-	defer resp.Body.Close()
 
-	var hosts []PXEHost
-	if err := json.NewDecoder(resp.Body).Decode(&hosts); err != nil {
-		log.Warnf("Failed to decode pxemanager response: %v", err)
-		return
+	for _, entry := range ordered {
+		applyOne(entry.source.Name(), s.fromEach[entry.source.Name()])
+		for serverName := range s.fromEach[entry.source.Name()] {
+			claimed[serverName] = true
+		}
+	}
+	// The manual source always participates, even though it isn't in
+	// s.sources.
+	if manual, ok := s.fromEach[manualSourceName]; ok {
+		applyOne(manualSourceName, manual)
 	}
 
-	s.mu.Lock()
+	return merged
+}
 
-	knownIPs := make(map[string]string) // IP -> name
-	for name, srv := range s.servers {
-		knownIPs[srv.IP] = name
+func diffServers(oldView, newView map[string]*Server) ChangeSet {
+	changes := ChangeSet{
+		Added:   make(map[string]*Server),
+		Removed: make(map[string]*Server),
+		Changed: make(map[string]*Server),
 	}
 
-	hasNewServers := false
-
-	for _, h := range hosts {
-		if h.IPMIIP == "" {
+	for name, srv := range newView {
+		old, existed := oldView[name]
+		if !existed {
+			changes.Added[name] = srv
 			continue
 		}
-
-		name := h.Hostname
-		if name == "" {
-			name = h.IPMIIP
-		}
-		// Remove domain suffix if present
-		if idx := strings.Index(name, "."); idx > 0 && net.ParseIP(name) == nil {
-			name = name[:idx]
+		if *old != *srv {
+			changes.Changed[name] = srv
 		}
+	}
 
-		if existingName, exists := knownIPs[h.IPMIIP]; exists && existingName != name {
-			existing := s.servers[existingName]
-			if h.MAC != "" {
-				existing.MAC = h.MAC
-			}
-			continue
-		}
-
-		if existing, exists := s.servers[name]; exists {
-			if h.MAC != "" {
-				existing.MAC = h.MAC
-			}
-		} else {
-			s.servers[name] = &Server{
-				IP:       h.IPMIIP,
-				Hostname: name,
-				Online:   true,
-				MAC:      h.MAC,
-			}
-			knownIPs[h.IPMIIP] = name
-			log.Infof("Discovered server from pxemanager: %s (%s)", name, h.IPMIIP)
-			hasNewServers = true
+	for name, srv := range oldView {
+		if _, stillExists := newView[name]; !stillExists {
+			changes.Removed[name] = srv
 		}
 	}
 
-	s.mu.Unlock()
-
-	if hasNewServers && s.onChange != nil {
-		go s.onChange(s.GetServers())
-	}
+	return changes
 }
@@ -0,0 +1,14 @@
+package discovery
+
+import "context"
+
+// DiscoverySource is a pluggable origin of server records. Each source runs
+// independently and reports its own current view of the world whenever that
+// view changes; Scanner merges the views from every configured source.
+type DiscoverySource interface {
+	// Name identifies the source for logging and priority resolution.
+	Name() string
+	// Run blocks, calling onUpdate with this source's full current view of
+	// servers every time it changes, until ctx is cancelled.
+	Run(ctx context.Context, onUpdate func(servers map[string]*Server))
+}
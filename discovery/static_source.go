@@ -0,0 +1,105 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// StaticFileEntry is one server record in a static discovery file.
+type StaticFileEntry struct {
+	Name string `json:"name" yaml:"name"`
+	IP   string `json:"ip" yaml:"ip"`
+	MAC  string `json:"mac" yaml:"mac"`
+}
+
+// StaticFileSource reads a YAML or JSON file of server records and
+// re-reads it whenever fsnotify reports a change, for operators who want to
+// hand-maintain a discovery list alongside the automatic sources.
+type StaticFileSource struct {
+	path string
+}
+
+func NewStaticFileSource(path string) *StaticFileSource {
+	return &StaticFileSource{path: path}
+}
+
+func (s *StaticFileSource) Name() string { return "static-file:" + filepath.Base(s.path) }
+
+func (s *StaticFileSource) Run(ctx context.Context, onUpdate func(servers map[string]*Server)) {
+	s.load(onUpdate)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warnf("static-file: failed to create watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	// Watch the containing directory rather than the file itself so editors
+	// that replace-via-rename (instead of writing in place) are still picked
+	// up.
+	dir := filepath.Dir(s.path)
+	if err := watcher.Add(dir); err != nil {
+		log.Warnf("static-file: failed to watch %s: %v", dir, err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) == filepath.Clean(s.path) {
+				s.load(onUpdate)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("static-file: watcher error: %v", err)
+		}
+	}
+}
+
+func (s *StaticFileSource) load(onUpdate func(servers map[string]*Server)) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("static-file: failed to read %s: %v", s.path, err)
+		}
+		return
+	}
+
+	var entries []StaticFileEntry
+	ext := filepath.Ext(s.path)
+	switch ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &entries)
+	default:
+		err = json.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		log.Warnf("static-file: failed to parse %s: %v", s.path, err)
+		return
+	}
+
+	servers := make(map[string]*Server, len(entries))
+	for _, e := range entries {
+		if e.Name == "" || e.IP == "" {
+			continue
+		}
+		servers[e.Name] = &Server{IP: e.IP, Hostname: e.Name, MAC: e.MAC, Online: true}
+	}
+
+	onUpdate(servers)
+}
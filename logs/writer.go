@@ -1,20 +1,52 @@
 package logs
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// indexIntervalBytes and indexInterval bound how often we record a
+	// timestamp -> offset mapping in a segment's sidecar .idx file: whichever
+	// threshold is hit first. Console output is bursty (idle for long
+	// stretches, then a flood during boot), so both a size and a time bound
+	// are needed to keep ReadRange's seek granularity reasonable in either
+	// case.
+	indexIntervalBytes = 64 * 1024
+	indexInterval      = time.Second
+
+	segmentTimeFormat = "2006-01-02_15-04-05"
+)
+
+// segment is one rotation's worth of console output: a zstd-compressed
+// stream plus the index that maps wall-clock time to offsets within the
+// decompressed stream it contains.
+type segment struct {
+	file      *os.File
+	enc       *zstd.Encoder
+	idxFile   *os.File
+	idxWriter *bufio.Writer
+
+	offset          int64 // bytes written to the decompressed stream so far
+	lastIndexOffset int64
+	lastIndexTime   time.Time
+}
+
 type Writer struct {
 	basePath      string
 	retentionDays int
-	files         map[string]*os.File
+	segments      map[string]*segment
 	mu            sync.Mutex
 }
 
@@ -22,7 +54,7 @@ func NewWriter(basePath string, retentionDays int) *Writer {
 	return &Writer{
 		basePath:      basePath,
 		retentionDays: retentionDays,
-		files:         make(map[string]*os.File),
+		segments:      make(map[string]*segment),
 	}
 }
 
@@ -30,33 +62,68 @@ func (w *Writer) Write(serverName string, data []byte) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	f, err := w.getOrCreateFile(serverName)
+	seg, err := w.getOrCreateSegment(serverName)
 	if err != nil {
 		return err
 	}
 
-	_, err = f.Write(data)
-	return err
+	now := time.Now()
+	needsIndex := seg.offset-seg.lastIndexOffset >= indexIntervalBytes || now.Sub(seg.lastIndexTime) >= indexInterval
+	if needsIndex {
+		if err := w.writeIndexEntry(seg, now, seg.offset); err != nil {
+			log.Warnf("Failed to write log index entry for %s: %v", serverName, err)
+		}
+	}
+
+	n, err := seg.enc.Write(data)
+	seg.offset += int64(n)
+	if err != nil {
+		return err
+	}
+
+	if needsIndex {
+		// Flush so the bytes just indexed are actually readable by ReadRange
+		// and GetCurrentLogContent before the segment is rotated.
+		return seg.enc.Flush()
+	}
+	return nil
 }
 
 func (w *Writer) Rotate(serverName string) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	// Close existing file
-	if f, exists := w.files[serverName]; exists {
-		f.Close()
-		delete(w.files, serverName)
+	if seg, exists := w.segments[serverName]; exists {
+		closeSegment(seg)
+		delete(w.segments, serverName)
 	}
 
-	// New file will be created on next write
 	log.Infof("Rotated log for %s", serverName)
 	return nil
 }
 
-func (w *Writer) getOrCreateFile(serverName string) (*os.File, error) {
-	if f, exists := w.files[serverName]; exists {
-		return f, nil
+// CanRotate reports whether there is an active segment with any data in it,
+// i.e. whether calling Rotate would actually start a fresh segment.
+func (w *Writer) CanRotate(serverName string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seg, exists := w.segments[serverName]
+	return exists && seg.offset > 0
+}
+
+func (w *Writer) writeIndexEntry(seg *segment, t time.Time, offset int64) error {
+	seg.lastIndexTime = t
+	seg.lastIndexOffset = offset
+	if _, err := fmt.Fprintf(seg.idxWriter, "%d %d\n", t.UnixNano(), offset); err != nil {
+		return err
+	}
+	return seg.idxWriter.Flush()
+}
+
+func (w *Writer) getOrCreateSegment(serverName string) (*segment, error) {
+	if seg, exists := w.segments[serverName]; exists {
+		return seg, nil
 	}
 
 	dir := filepath.Join(w.basePath, serverName)
@@ -64,35 +131,270 @@ func (w *Writer) getOrCreateFile(serverName string) (*os.File, error) {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	// Try to continue existing current.log if it exists
-	symlinkPath := filepath.Join(dir, "current.log")
-	if target, err := os.Readlink(symlinkPath); err == nil {
-		existingPath := filepath.Join(dir, target)
-		if f, err := os.OpenFile(existingPath, os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-			w.files[serverName] = f
-			log.Infof("Continuing existing log file: %s", existingPath)
-			return f, nil
+	now := time.Now()
+	logName, idxName := uniqueSegmentNames(dir, now.Format(segmentTimeFormat))
+	logPath := filepath.Join(dir, logName)
+	idxPath := filepath.Join(dir, idxName)
+
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log segment: %w", err)
+	}
+
+	enc, err := zstd.NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+
+	idxFile, err := os.OpenFile(idxPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		enc.Close()
+		f.Close()
+		return nil, fmt.Errorf("failed to create log index: %w", err)
+	}
+
+	seg := &segment{
+		file:      f,
+		enc:       enc,
+		idxFile:   idxFile,
+		idxWriter: bufio.NewWriter(idxFile),
+	}
+	w.writeIndexEntry(seg, now, 0)
+	w.segments[serverName] = seg
+
+	symlinkLog := filepath.Join(dir, "current.log.zst")
+	os.Remove(symlinkLog)
+	os.Symlink(logName, symlinkLog)
+
+	symlinkIdx := filepath.Join(dir, "current.idx")
+	os.Remove(symlinkIdx)
+	os.Symlink(idxName, symlinkIdx)
+
+	log.Infof("Created log segment: %s", logPath)
+	return seg, nil
+}
+
+// uniqueSegmentNames returns log/idx filenames for the given timestamp base
+// (segmentTimeFormat, 1-second resolution), disambiguating with a "-N"
+// suffix if a segment from the same wall-clock second already exists on
+// disk. Without this, a Rotate immediately followed by new console data (or
+// a quick SOL reconnect) would silently O_TRUNC and overwrite the segment
+// that was just closed.
+func uniqueSegmentNames(dir, base string) (logName, idxName string) {
+	name := base
+	for n := 0; ; n++ {
+		if n > 0 {
+			name = fmt.Sprintf("%s-%d", base, n)
 		}
+		logName = name + ".log.zst"
+		if _, err := os.Stat(filepath.Join(dir, logName)); os.IsNotExist(err) {
+			return logName, name + ".idx"
+		}
+	}
+}
+
+// parseSegmentBase parses a segment filename's base (without extension) as
+// segmentTimeFormat, also accepting the "-N" disambiguator uniqueSegmentNames
+// appends for same-second segments.
+func parseSegmentBase(base string) (time.Time, bool) {
+	if t, err := time.ParseInLocation(segmentTimeFormat, base, time.Local); err == nil {
+		return t, true
+	}
+	if idx := strings.LastIndex(base, "-"); idx > 0 {
+		if t, err := time.ParseInLocation(segmentTimeFormat, base[:idx], time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func closeSegment(seg *segment) {
+	seg.enc.Close()
+	seg.file.Close()
+	seg.idxWriter.Flush()
+	seg.idxFile.Close()
+}
+
+// ReadRange returns a bounded, decoded window of console output for a server
+// between from and to, spanning as many rotated (and the active) segments as
+// necessary. Each segment's .idx sidecar gives the decompressed offsets
+// nearest from and to: decoding stops at the first index entry past to, but
+// a segment is a single zstd stream, so the bytes before from are still
+// decoded and discarded rather than skipped.
+//
+// This holds w.mu for its full duration, same as Write/Rotate, since the
+// range being read may include the segment currently being appended to;
+// without that, a concurrent Write's flush or a Rotate closing/replacing the
+// active segment could be observed mid-write and yield a truncated or
+// malformed zstd frame. That does mean a ReadRange call briefly blocks
+// writes for every server, not just the one being read.
+func (w *Writer) ReadRange(serverName string, from, to time.Time) (io.ReadCloser, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if seg, exists := w.segments[serverName]; exists {
+		seg.enc.Flush()
+		seg.file.Sync()
 	}
 
-	// Create new log file
-	filename := time.Now().Format("2006-01-02_15-04-05") + ".log"
-	path := filepath.Join(dir, filename)
+	dir := filepath.Join(w.basePath, serverName)
 
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create log file: %w", err)
+		if os.IsNotExist(err) {
+			return io.NopCloser(strings.NewReader("")), nil
+		}
+		return nil, err
 	}
 
-	w.files[serverName] = f
+	type segmentFile struct {
+		logPath, idxPath string
+		startTime        time.Time
+		seq              int // uniqueSegmentNames' "-N" suffix, 0 if none
+	}
 
-	// Update current.log symlink
-	os.Remove(symlinkPath)
-	os.Symlink(filename, symlinkPath)
+	var segs []segmentFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == "current.log.zst" || !strings.HasSuffix(name, ".log.zst") {
+			continue
+		}
+		base := strings.TrimSuffix(name, ".log.zst")
+		startTime, ok := parseSegmentBase(base)
+		if !ok {
+			continue
+		}
+		seq := 0
+		if len(base) > len(segmentTimeFormat) {
+			seq, _ = strconv.Atoi(base[len(segmentTimeFormat)+1:])
+		}
+		segs = append(segs, segmentFile{
+			logPath:   filepath.Join(dir, name),
+			idxPath:   filepath.Join(dir, base+".idx"),
+			startTime: startTime,
+			seq:       seq,
+		})
+	}
+	// startTime is truncated to segmentTimeFormat's 1-second resolution, so
+	// two segments rotated within the same second compare equal; those are
+	// ordered by their "-N" suffix, which is their creation order. Name order
+	// would not do: "X-1.log.zst" sorts before "X.log.zst".
+	sort.Slice(segs, func(i, j int) bool {
+		if !segs[i].startTime.Equal(segs[j].startTime) {
+			return segs[i].startTime.Before(segs[j].startTime)
+		}
+		return segs[i].seq < segs[j].seq
+	})
+
+	var readers []io.Reader
+	var closers []io.Closer
+
+	for i, seg := range segs {
+		segEnd := time.Now()
+		if i+1 < len(segs) {
+			segEnd = segs[i+1].startTime
+		}
+		if segEnd.Before(from) || seg.startTime.After(to) {
+			continue
+		}
+
+		idxEntries, err := readIndex(seg.idxPath)
+		if err != nil || len(idxEntries) == 0 {
+			continue
+		}
+
+		var startOffset int64
+		endOffset := int64(-1)
+		for _, e := range idxEntries {
+			if !e.at.After(from) {
+				startOffset = e.offset
+			}
+			if endOffset < 0 && e.at.After(to) {
+				endOffset = e.offset
+			}
+		}
+
+		f, err := os.Open(seg.logPath)
+		if err != nil {
+			continue
+		}
+		dec, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			continue
+		}
+
+		if startOffset > 0 {
+			if _, err := io.CopyN(io.Discard, dec, startOffset); err != nil && err != io.EOF {
+				dec.Close()
+				f.Close()
+				continue
+			}
+		}
+
+		var r io.Reader = dec
+		if endOffset >= 0 {
+			r = io.LimitReader(dec, endOffset-startOffset)
+		}
+
+		readers = append(readers, r)
+		closers = append(closers, closerFunc(func() error { dec.Close(); return nil }), f)
+	}
+
+	return &rangeReader{r: io.MultiReader(readers...), closers: closers}, nil
+}
 
-	log.Infof("Created log file: %s", path)
+type rangeReader struct {
+	r       io.Reader
+	closers []io.Closer
+}
+
+func (rr *rangeReader) Read(p []byte) (int, error) {
+	return rr.r.Read(p)
+}
+
+func (rr *rangeReader) Close() error {
+	var firstErr error
+	for _, c := range rr.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+type closerFunc func() error
 
-	return f, nil
+func (f closerFunc) Close() error { return f() }
+
+type indexEntry struct {
+	at     time.Time
+	offset int64
+}
+
+func readIndex(path string) ([]indexEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []indexEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		nanos, err1 := strconv.ParseInt(fields[0], 10, 64)
+		offset, err2 := strconv.ParseInt(fields[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		entries = append(entries, indexEntry{at: time.Unix(0, nanos), offset: offset})
+	}
+	return entries, scanner.Err()
 }
 
 func (w *Writer) ListLogs(serverName string) ([]string, error) {
@@ -108,8 +410,9 @@ func (w *Writer) ListLogs(serverName string) ([]string, error) {
 
 	var logs []string
 	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".log" && entry.Name() != "current.log" {
-			logs = append(logs, entry.Name())
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".log.zst") && name != "current.log.zst" {
+			logs = append(logs, name)
 		}
 	}
 
@@ -125,23 +428,29 @@ func (w *Writer) GetLogPath(serverName, filename string) string {
 
 func (w *Writer) GetCurrentLogContent(serverName string) ([]byte, error) {
 	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	// Sync current file to disk first
-	if f, exists := w.files[serverName]; exists {
-		f.Sync()
+	if seg, exists := w.segments[serverName]; exists {
+		seg.enc.Flush()
+		seg.file.Sync()
 	}
+	w.mu.Unlock()
 
-	// Read the current log file
-	currentPath := filepath.Join(w.basePath, serverName, "current.log")
-	data, err := os.ReadFile(currentPath)
+	currentPath := filepath.Join(w.basePath, serverName, "current.log.zst")
+	f, err := os.Open(currentPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []byte{}, nil
 		}
 		return nil, err
 	}
-	return data, nil
+	defer f.Close()
+
+	dec, err := zstd.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+
+	return io.ReadAll(dec)
 }
 
 func (w *Writer) Cleanup() {
@@ -168,7 +477,8 @@ func (w *Writer) Cleanup() {
 		}
 
 		for _, logFile := range logFiles {
-			if logFile.IsDir() || filepath.Ext(logFile.Name()) != ".log" {
+			name := logFile.Name()
+			if logFile.IsDir() || !strings.HasSuffix(name, ".log.zst") || name == "current.log.zst" {
 				continue
 			}
 
@@ -178,9 +488,10 @@ func (w *Writer) Cleanup() {
 			}
 
 			if info.ModTime().Before(cutoff) {
-				path := filepath.Join(serverPath, logFile.Name())
-				os.Remove(path)
-				log.Infof("Cleaned up old log: %s", path)
+				base := strings.TrimSuffix(name, ".log.zst")
+				os.Remove(filepath.Join(serverPath, name))
+				os.Remove(filepath.Join(serverPath, base+".idx"))
+				log.Infof("Cleaned up old log: %s", filepath.Join(serverPath, name))
 			}
 		}
 	}
@@ -190,20 +501,19 @@ func (w *Writer) Close() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	for _, f := range w.files {
-		f.Close()
+	for _, seg := range w.segments {
+		closeSegment(seg)
 	}
-	w.files = make(map[string]*os.File)
+	w.segments = make(map[string]*segment)
 }
 
 func (w *Writer) ClearLogs(serverName string) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	// Close the current file if open
-	if f, exists := w.files[serverName]; exists {
-		f.Close()
-		delete(w.files, serverName)
+	if seg, exists := w.segments[serverName]; exists {
+		closeSegment(seg)
+		delete(w.segments, serverName)
 	}
 
 	dir := filepath.Join(w.basePath, serverName)
@@ -231,11 +541,10 @@ func (w *Writer) ClearAllLogs() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	// Close all open files
-	for _, f := range w.files {
-		f.Close()
+	for _, seg := range w.segments {
+		closeSegment(seg)
 	}
-	w.files = make(map[string]*os.File)
+	w.segments = make(map[string]*segment)
 
 	entries, err := os.ReadDir(w.basePath)
 	if err != nil {
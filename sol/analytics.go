@@ -0,0 +1,187 @@
+package sol
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxRecentRebootsPerServer = 20
+	maxBootDurationsPerServer = 50
+)
+
+// ServerAnalytics is the per-server rollup exposed via Manager.GetAnalytics
+// and the /api/analytics/{server} endpoint.
+type ServerAnalytics struct {
+	ServerName      string              `json:"server_name"`
+	TotalReboots    int                 `json:"total_reboots"`
+	RebootsByCause  map[RebootCause]int `json:"reboots_by_cause"`
+	RecentReboots   []RebootEvent       `json:"recent_reboots"`
+	BootDurations   []time.Duration     `json:"boot_durations_ns"`
+	MeanTimeBetween time.Duration       `json:"mean_time_between_reboots_ns"`
+}
+
+// Analytics tracks reboot history and boot timing per server, persisted as a
+// single JSON snapshot so counts survive a restart.
+type Analytics struct {
+	dataPath string
+	mu       sync.Mutex
+	servers  map[string]*ServerAnalytics
+}
+
+func NewAnalytics(dataPath string) *Analytics {
+	a := &Analytics{
+		dataPath: dataPath,
+		servers:  make(map[string]*ServerAnalytics),
+	}
+	a.load()
+	return a
+}
+
+func (a *Analytics) snapshotPath() string {
+	if a.dataPath == "" {
+		return ""
+	}
+	return filepath.Join(a.dataPath, "analytics.json")
+}
+
+func (a *Analytics) load() {
+	path := a.snapshotPath()
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("analytics: failed to read snapshot %s: %v", path, err)
+		}
+		return
+	}
+
+	var servers map[string]*ServerAnalytics
+	if err := json.Unmarshal(data, &servers); err != nil {
+		log.Warnf("analytics: failed to parse snapshot %s: %v", path, err)
+		return
+	}
+	a.servers = servers
+}
+
+// save persists the current state. Best-effort: failures are logged, not
+// returned, since analytics is a convenience view rather than a source of
+// truth.
+func (a *Analytics) save() {
+	path := a.snapshotPath()
+	if path == "" {
+		return
+	}
+
+	data, err := json.Marshal(a.servers)
+	if err != nil {
+		log.Warnf("analytics: failed to marshal snapshot: %v", err)
+		return
+	}
+	if err := os.MkdirAll(a.dataPath, 0755); err != nil {
+		log.Warnf("analytics: failed to create data dir %s: %v", a.dataPath, err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warnf("analytics: failed to write snapshot %s: %v", path, err)
+	}
+}
+
+func (a *Analytics) getOrCreate(serverName string) *ServerAnalytics {
+	sa, exists := a.servers[serverName]
+	if !exists {
+		sa = &ServerAnalytics{
+			ServerName:     serverName,
+			RebootsByCause: make(map[RebootCause]int),
+		}
+		a.servers[serverName] = sa
+	}
+	return sa
+}
+
+// RecordReboot updates reboot counts, cause breakdown, recent history, and
+// mean-time-between-reboots for the event's server.
+func (a *Analytics) RecordReboot(event RebootEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sa := a.getOrCreate(event.ServerName)
+	sa.TotalReboots++
+	sa.RebootsByCause[event.ClassifiedCause]++
+
+	sa.RecentReboots = append(sa.RecentReboots, event)
+	if len(sa.RecentReboots) > maxRecentRebootsPerServer {
+		sa.RecentReboots = sa.RecentReboots[len(sa.RecentReboots)-maxRecentRebootsPerServer:]
+	}
+
+	if len(sa.RecentReboots) >= 2 {
+		first := sa.RecentReboots[0].DetectedAt
+		last := sa.RecentReboots[len(sa.RecentReboots)-1].DetectedAt
+		sa.MeanTimeBetween = last.Sub(first) / time.Duration(len(sa.RecentReboots)-1)
+	}
+
+	a.save()
+}
+
+// RecordBootDuration appends a boot-time sample (dwell between the OS being
+// lost and the OS coming back) for a server.
+func (a *Analytics) RecordBootDuration(serverName string, dwell time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sa := a.getOrCreate(serverName)
+	sa.BootDurations = append(sa.BootDurations, dwell)
+	if len(sa.BootDurations) > maxBootDurationsPerServer {
+		sa.BootDurations = sa.BootDurations[len(sa.BootDurations)-maxBootDurationsPerServer:]
+	}
+
+	a.save()
+}
+
+func (a *Analytics) GetServerAnalytics(serverName string) *ServerAnalytics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sa, exists := a.servers[serverName]
+	if !exists {
+		return &ServerAnalytics{ServerName: serverName, RebootsByCause: make(map[RebootCause]int)}
+	}
+	return sa.clone()
+}
+
+func (a *Analytics) GetAllAnalytics() map[string]*ServerAnalytics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make(map[string]*ServerAnalytics, len(a.servers))
+	for k, v := range a.servers {
+		result[k] = v.clone()
+	}
+	return result
+}
+
+// clone deep-copies a ServerAnalytics (including its map and slices) so
+// callers can read it after Analytics.mu is released without racing
+// RecordReboot/RecordBootDuration, which keep mutating the original
+// (including RebootsByCause, a map) from the SOL read loop goroutine.
+func (sa *ServerAnalytics) clone() *ServerAnalytics {
+	out := *sa
+
+	out.RebootsByCause = make(map[RebootCause]int, len(sa.RebootsByCause))
+	for k, v := range sa.RebootsByCause {
+		out.RebootsByCause[k] = v
+	}
+
+	out.RecentReboots = append([]RebootEvent(nil), sa.RecentReboots...)
+	out.BootDurations = append([]time.Duration(nil), sa.BootDurations...)
+
+	return &out
+}
@@ -0,0 +1,62 @@
+package sol
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// BackendType selects which SOL transport implementation to use for a server.
+type BackendType string
+
+const (
+	// BackendNativeIPMI speaks IPMI 2.0 RMCP+/RAKP/SOL directly over UDP.
+	// This is the default and avoids the ipmitool/TTY hangs seen on arm64.
+	BackendNativeIPMI BackendType = "ipmi"
+	// BackendRedfishSerial uses the Redfish SerialConsole endpoint over HTTPS.
+	BackendRedfishSerial BackendType = "redfish"
+	// BackendIPMITool selects IPMIToolBackend, whose Connect always returns an
+	// error; it does not run the external ipmitool binary.
+	BackendIPMITool BackendType = "ipmitool"
+)
+
+// BackendConfig carries the per-server connection parameters a Backend needs
+// to establish a SOL session. Fields not relevant to a given backend are
+// ignored by it.
+type BackendConfig struct {
+	ServerName string
+	IP         string
+	Username   string
+	Password   string
+	// RedfishSystemID is the Redfish ComputerSystem id (e.g. "1"), used only
+	// by BackendRedfishSerial.
+	RedfishSystemID string
+	// InsecureSkipVerify disables TLS verification for Redfish, for BMCs with
+	// self-signed certs.
+	InsecureSkipVerify bool
+}
+
+// Backend is a pluggable SOL transport. Implementations establish whatever
+// session handshake their protocol requires and return a stream of the raw
+// console bytes in both directions.
+type Backend interface {
+	Connect(ctx context.Context, cfg BackendConfig) (io.ReadWriteCloser, error)
+}
+
+// NewBackend returns the Backend implementation for the given type, or an
+// error if the type is unknown. Operators select the backend per-server via
+// config so they can match the transport to their BMC vendor (native IPMI
+// for Supermicro/iDRAC/iLO, Redfish for vendors with SOL disabled over IPMI).
+// BackendIPMITool is accepted, but its Connect always fails.
+func NewBackend(t BackendType) (Backend, error) {
+	switch t {
+	case "", BackendNativeIPMI:
+		return &NativeIPMIBackend{}, nil
+	case BackendRedfishSerial:
+		return &RedfishSerialBackend{}, nil
+	case BackendIPMITool:
+		return &IPMIToolBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown SOL backend %q", t)
+	}
+}
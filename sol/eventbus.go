@@ -0,0 +1,148 @@
+package sol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EventSink receives every RebootEvent published on an EventBus. Publish is
+// called from a dedicated goroutine per event, so implementations don't need
+// to worry about blocking the console read loop, but should still apply
+// their own timeout.
+type EventSink interface {
+	Publish(event RebootEvent) error
+}
+
+const defaultRingBufferSize = 200
+
+// EventBus fans reboot events out to configurable sinks (webhook, NATS, ...)
+// and keeps a local ring buffer of recent events for the UI, so operators
+// can alert on panics vs. clean reboots differently and feed a fleet
+// dashboard without every consumer re-parsing console text itself.
+type EventBus struct {
+	mu    sync.RWMutex
+	sinks []EventSink
+
+	ringMu   sync.Mutex
+	ring     []RebootEvent
+	ringSize int
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		ringSize: defaultRingBufferSize,
+	}
+}
+
+// AddSink registers a sink that every future event is fanned out to.
+func (b *EventBus) AddSink(sink EventSink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, sink)
+}
+
+// Publish records the event in the ring buffer and fans it out to every
+// registered sink asynchronously.
+func (b *EventBus) Publish(event RebootEvent) {
+	b.ringMu.Lock()
+	b.ring = append(b.ring, event)
+	if len(b.ring) > b.ringSize {
+		b.ring = b.ring[len(b.ring)-b.ringSize:]
+	}
+	b.ringMu.Unlock()
+
+	b.mu.RLock()
+	sinks := make([]EventSink, len(b.sinks))
+	copy(sinks, b.sinks)
+	b.mu.RUnlock()
+
+	for _, sink := range sinks {
+		go func(sink EventSink) {
+			if err := sink.Publish(event); err != nil {
+				log.Warnf("Reboot event sink failed for %s: %v", event.ServerName, err)
+			}
+		}(sink)
+	}
+}
+
+// Recent returns up to the last N buffered events, newest last.
+func (b *EventBus) Recent() []RebootEvent {
+	b.ringMu.Lock()
+	defer b.ringMu.Unlock()
+
+	result := make([]RebootEvent, len(b.ring))
+	copy(result, b.ring)
+	return result
+}
+
+// ServeHTTP exposes the ring buffer as JSON, for the UI to poll.
+func (b *EventBus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(b.Recent()); err != nil {
+		log.Warnf("Failed to encode reboot events: %v", err)
+	}
+}
+
+// WebhookSink POSTs each event as JSON to a configured URL.
+type WebhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		URL:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (s *WebhookSink) Publish(event RebootEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal reboot event: %w", err)
+	}
+
+	resp, err := s.client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// NATSConn is the subset of *nats.Conn (github.com/nats-io/nats.go) that
+// NATSSink needs, so callers can also pass a JetStream-backed publisher.
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink publishes each event as JSON to a NATS (or JetStream) subject.
+type NATSSink struct {
+	Conn    NATSConn
+	Subject string
+}
+
+func NewNATSSink(conn NATSConn, subject string) *NATSSink {
+	return &NATSSink{Conn: conn, Subject: subject}
+}
+
+func (s *NATSSink) Publish(event RebootEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal reboot event: %w", err)
+	}
+	if err := s.Conn.Publish(s.Subject, body); err != nil {
+		return fmt.Errorf("publish to NATS subject %s: %w", s.Subject, err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package sol
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// IPMIToolBackend is the backend type for the external ipmitool binary.
+// Connect always returns an error: it does not start ipmitool, so a server
+// set to this backend never gets a SOL connection. ipmitool is known to hang
+// its TTY under arm64 containers, which is why BackendNativeIPMI is the
+// default.
+type IPMIToolBackend struct{}
+
+func (b *IPMIToolBackend) Connect(ctx context.Context, cfg BackendConfig) (io.ReadWriteCloser, error) {
+	return nil, fmt.Errorf("ipmitool backend not available in this build: use BackendNativeIPMI or BackendRedfishSerial")
+}
@@ -0,0 +1,275 @@
+package sol
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ipmiRMCPPort = 623
+
+	solReadTimeout    = 250 * time.Millisecond
+	keepaliveInterval = 20 * time.Second
+)
+
+// NativeIPMIBackend speaks IPMI 2.0 RMCP+/RAKP/SOL directly over UDP,
+// avoiding a dependency on the ipmitool subprocess.
+type NativeIPMIBackend struct{}
+
+func (b *NativeIPMIBackend) Connect(ctx context.Context, cfg BackendConfig) (io.ReadWriteCloser, error) {
+	raddr := &net.UDPAddr{IP: net.ParseIP(cfg.IP), Port: ipmiRMCPPort}
+	if raddr.IP == nil {
+		resolved, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", cfg.IP, ipmiRMCPPort))
+		if err != nil {
+			return nil, fmt.Errorf("resolve BMC address: %w", err)
+		}
+		raddr = resolved
+	}
+
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, fmt.Errorf("dial BMC: %w", err)
+	}
+
+	sess, err := negotiateSession(conn, cfg.Username, cfg.Password)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("establish IPMI session: %w", err)
+	}
+
+	if err := activateSOL(conn, sess); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("activate SOL payload: %w", err)
+	}
+
+	sc := &solConn{
+		conn:       conn,
+		sess:       sess,
+		serverName: cfg.ServerName,
+	}
+	sc.ctx, sc.cancel = context.WithCancel(ctx)
+	go sc.keepaliveLoop()
+
+	return sc, nil
+}
+
+// negotiateSession performs RMCP+ Open Session and the RAKP 1-4 exchange,
+// returning the derived session keys and sequence state.
+func negotiateSession(conn *net.UDPConn, username, password string) (*sessionWireSession, error) {
+	consoleSessionID, err := randomSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	openReq := buildOpenSessionRequest(consoleSessionID)
+	if err := sendRMCPPayload(conn, payloadTypeOpenSession, openReq); err != nil {
+		return nil, err
+	}
+	_, respBody, err := recvRMCPPayload(conn)
+	if err != nil {
+		return nil, fmt.Errorf("open session: %w", err)
+	}
+	openResp, err := parseOpenSessionResponse(respBody)
+	if err != nil {
+		return nil, err
+	}
+
+	consoleRand, err := randomBytes16()
+	if err != nil {
+		return nil, err
+	}
+	rakp1 := buildRAKPMessage1(openResp.managedSystemSessionID, consoleRand, username)
+	if err := sendRMCPPayload(conn, payloadTypeRAKP1, rakp1); err != nil {
+		return nil, err
+	}
+	_, rakp2Body, err := recvRMCPPayload(conn)
+	if err != nil {
+		return nil, fmt.Errorf("RAKP1: %w", err)
+	}
+	rakp2, err := parseRAKPMessage2(rakp2Body)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedRAKP2Auth := computeRAKP2Auth(password, consoleSessionID, openResp.managedSystemSessionID, consoleRand, rakp2.bmcRand, rakp2.bmcGUID, username)
+	if len(rakp2.keyExchangeAuth) == 0 || !hmac.Equal(rakp2.keyExchangeAuth, expectedRAKP2Auth) {
+		return nil, fmt.Errorf("RAKP2: BMC authentication code did not match (wrong password, or a rogue/MITM responder)")
+	}
+
+	sik := computeSIK(password, consoleRand, rakp2.bmcRand, username)
+	k1, k2 := deriveSessionKeys(sik)
+
+	rakp3Auth := computeRAKP3Auth(password, rakp2.consoleSessionID, rakp2.bmcRand, username)
+	rakp3 := buildRAKPMessage3(openResp.managedSystemSessionID, rakp3Auth)
+	if err := sendRMCPPayload(conn, payloadTypeRAKP3, rakp3); err != nil {
+		return nil, err
+	}
+	_, rakp4Body, err := recvRMCPPayload(conn)
+	if err != nil {
+		return nil, fmt.Errorf("RAKP3: %w", err)
+	}
+	if err := parseRAKPMessage4(rakp4Body); err != nil {
+		return nil, err
+	}
+
+	return &sessionWireSession{
+		managedSystemSessionID: openResp.managedSystemSessionID,
+		remoteConsoleSessionID: consoleSessionID,
+		sik:                    sik,
+		k1:                     k1,
+		k2:                     k2,
+	}, nil
+}
+
+func activateSOL(conn *net.UDPConn, sess *sessionWireSession) error {
+	req := buildIPMIRequest(netFnApp, cmdActivateSOL, buildActivateSOLRequest())
+	pkt, err := buildSessionPacket(sess, payloadTypeIPMI, req)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(append(rmcpHeader(), pkt...)); err != nil {
+		return err
+	}
+	// Best-effort: read and discard the command response; the BMC switches
+	// to streaming SOL payload type traffic afterwards.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 256)
+	_, _ = conn.Read(buf)
+	conn.SetReadDeadline(time.Time{})
+	return nil
+}
+
+func sendRMCPPayload(conn *net.UDPConn, payloadType byte, payload []byte) error {
+	// Pre-session messages use auth-type RMCP+ with a zero session header.
+	header := []byte{ipmiAuthTypeRMCPPlus, payloadType, 0, 0, 0, 0, 0, 0, 0, 0, byte(len(payload)), byte(len(payload) >> 8)}
+	pkt := append(rmcpHeader(), append(header, payload...)...)
+	_, err := conn.Write(pkt)
+	return err
+}
+
+func recvRMCPPayload(conn *net.UDPConn) (byte, []byte, error) {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return 0, nil, err
+	}
+	if n < 16 {
+		return 0, nil, fmt.Errorf("short RMCP response: %d bytes", n)
+	}
+	payloadType := buf[5] & 0x3f
+	length := int(buf[14]) | int(buf[15])<<8
+	if 16+length > n {
+		return 0, nil, fmt.Errorf("truncated RMCP response")
+	}
+	return payloadType, buf[16 : 16+length], nil
+}
+
+// solConn is the io.ReadWriteCloser handed to the Manager for an active SOL
+// session: raw console bytes in, raw console bytes out, with a background
+// keepalive and a clean deactivate on Close.
+type solConn struct {
+	conn       *net.UDPConn
+	sess       *sessionWireSession
+	serverName string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeMu sync.Mutex
+}
+
+func (c *solConn) Read(p []byte) (int, error) {
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(solReadTimeout))
+		buf := make([]byte, 2048)
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				select {
+				case <-c.ctx.Done():
+					return 0, io.EOF
+				default:
+					continue
+				}
+			}
+			return 0, err
+		}
+
+		if n < 4 {
+			continue
+		}
+		// Strip the 4-byte RMCP header; parseSessionPacket starts at the
+		// session header.
+		payloadType, body, perr := parseSessionPacket(c.sess, buf[4:n])
+		if perr != nil {
+			log.Warnf("SOL %s: dropping malformed packet: %v", c.serverName, perr)
+			continue
+		}
+		if payloadType != payloadTypeSOL || len(body) <= 4 {
+			// Non-SOL traffic (command responses) or a keepalive ack with no
+			// console bytes attached.
+			continue
+		}
+		// SOL payload: 4-byte status/ack header followed by console data.
+		copy(p, body[4:])
+		return len(body) - 4, nil
+	}
+}
+
+func (c *solConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	solHeader := []byte{0x00, 0x00, 0x00, 0x00} // seq/ack assigned by BMC-facing fields left zero; relies on session seq for ordering
+	payload := append(solHeader, p...)
+
+	pkt, err := buildSessionPacket(c.sess, payloadTypeSOL, payload)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.conn.Write(append(rmcpHeader(), pkt...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *solConn) keepaliveLoop() {
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			// An empty SOL payload acts as a keepalive/sequence nudge without
+			// injecting console input.
+			if _, err := c.Write(nil); err != nil {
+				log.Warnf("SOL %s: keepalive failed: %v", c.serverName, err)
+			}
+		}
+	}
+}
+
+func (c *solConn) Close() error {
+	c.cancel()
+
+	deactivateData := []byte{payloadTypeSOL, 0x01, 0x00, 0x00, 0x00, 0x00} // instance 1, deactivate
+	deactivate := buildIPMIRequest(netFnApp, cmdDeactivateSOL, deactivateData)
+	if pkt, err := buildSessionPacket(c.sess, payloadTypeIPMI, deactivate); err == nil {
+		c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		c.conn.Write(append(rmcpHeader(), pkt...))
+	}
+
+	return c.conn.Close()
+}
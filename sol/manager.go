@@ -3,6 +3,7 @@ package sol
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -15,7 +16,7 @@ type Session struct {
 	Connected   bool
 	LastError   string
 	cancel      context.CancelFunc
-	subscribers map[chan []byte]struct{}
+	subscribers map[*subscriber]struct{}
 	subMu       sync.RWMutex
 }
 
@@ -27,23 +28,55 @@ type Manager struct {
 	logWriter      LogWriter
 	rebootDetector *RebootDetector
 	analytics      *Analytics
+	backends       map[string]BackendType
+	eventBus       *EventBus
 }
 
 type LogWriter interface {
 	Write(serverName string, data []byte) error
 	Rotate(serverName string) error
 	CanRotate(serverName string) bool
+	ReadRange(serverName string, from, to time.Time) (io.ReadCloser, error)
+	GetCurrentLogContent(serverName string) ([]byte, error)
 }
 
 func NewManager(username, password string, logWriter LogWriter, rebootDetector *RebootDetector, dataPath string) *Manager {
-	return &Manager{
+	m := &Manager{
 		username:       username,
 		password:       password,
 		sessions:       make(map[string]*Session),
 		logWriter:      logWriter,
 		rebootDetector: rebootDetector,
 		analytics:      NewAnalytics(dataPath),
+		backends:       make(map[string]BackendType),
+		eventBus:       NewEventBus(),
 	}
+
+	if rebootDetector != nil {
+		rebootDetector.OnBootComplete(func(serverName string, dwell time.Duration) {
+			m.analytics.RecordBootDuration(serverName, dwell)
+			metricBootDuration.WithLabelValues(serverName).Observe(dwell.Seconds())
+			metricBIOSDwell.WithLabelValues(serverName).Observe(dwell.Seconds())
+		})
+	}
+
+	return m
+}
+
+// EventBus returns the Manager's reboot EventBus, so callers can register
+// sinks (webhook, NATS, ...) and mount its HTTP handler.
+func (m *Manager) EventBus() *EventBus {
+	return m.eventBus
+}
+
+// SetBackend selects which SOL transport to use for a server. Operators set
+// this per-server so each BMC vendor can use the transport that works for
+// it (e.g. Redfish for a BMC with IPMI SOL disabled). Must be called before
+// StartSession; it has no effect on an already-running session.
+func (m *Manager) SetBackend(serverName string, backendType BackendType) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backends[serverName] = backendType
 }
 
 func (m *Manager) GetAnalytics(serverName string) *ServerAnalytics {
@@ -54,6 +87,15 @@ func (m *Manager) GetAllAnalytics() map[string]*ServerAnalytics {
 	return m.analytics.GetAllAnalytics()
 }
 
+// ReadLogRange returns decoded console output for a server within a time
+// range, for scrollback over compressed, rotated log segments.
+func (m *Manager) ReadLogRange(serverName string, from, to time.Time) (io.ReadCloser, error) {
+	if m.logWriter == nil {
+		return nil, fmt.Errorf("no log writer configured")
+	}
+	return m.logWriter.ReadRange(serverName, from, to)
+}
+
 func (m *Manager) StartSession(serverName, ip string) {
 	m.mu.Lock()
 	if existing, exists := m.sessions[serverName]; exists {
@@ -68,7 +110,7 @@ func (m *Manager) StartSession(serverName, ip string) {
 		IP:          ip,
 		Connected:   false,
 		cancel:      cancel,
-		subscribers: make(map[chan []byte]struct{}),
+		subscribers: make(map[*subscriber]struct{}),
 	}
 	m.sessions[serverName] = session
 	m.mu.Unlock()
@@ -84,10 +126,12 @@ func (m *Manager) StopSession(serverName string) {
 		if session.cancel != nil {
 			session.cancel()
 		}
-		// Close all subscriber channels
+		// Stop every subscriber; each drains whatever it has buffered and
+		// closes its own output channel.
 		session.subMu.Lock()
-		for ch := range session.subscribers {
-			close(ch)
+		for sub := range session.subscribers {
+			sub.stop()
+			metricSubscribers.WithLabelValues(serverName).Dec()
 		}
 		session.subscribers = nil
 		session.subMu.Unlock()
@@ -112,32 +156,74 @@ func (m *Manager) GetSessions() map[string]*Session {
 	return result
 }
 
-func (m *Manager) Subscribe(serverName string) (<-chan []byte, func()) {
+// Subscribe attaches a new subscriber to a server's SOL session. Console
+// data is fed through a per-subscriber bounded ring buffer drained by its own
+// goroutine, so a slow or stalled subscriber can't hold up delivery to
+// anyone else; opts.OnLag decides what happens to that slow subscriber
+// itself. If opts.ReplayFromCurrentLog is set, the tail of the current log
+// segment (at most the subscriber's buffer size, so the replay alone never
+// trips OnLag) is enqueued atomically with registration, so no live bytes can be
+// published to this subscriber before its replay data.
+//
+// The returned channel is closed once the subscriber is stopped (via the
+// returned function, session teardown, or ctx being done), possibly
+// preceded by a final {"error":"lagged"} message if it was evicted for
+// lagging.
+func (m *Manager) Subscribe(ctx context.Context, serverName string, opts SubscribeOptions) (<-chan []byte, func(), error) {
 	m.mu.RLock()
 	session, exists := m.sessions[serverName]
 	m.mu.RUnlock()
 
 	if !exists {
-		return nil, nil
+		return nil, nil, fmt.Errorf("no active SOL session for %s", serverName)
 	}
 
-	ch := make(chan []byte, 100)
+	sub := newSubscriber(serverName, opts)
+	subCtx, cancel := context.WithCancel(ctx)
+	go sub.run(subCtx)
 
 	session.subMu.Lock()
-	session.subscribers[ch] = struct{}{}
+	session.subscribers[sub] = struct{}{}
+	if opts.ReplayFromCurrentLog && m.logWriter != nil {
+		if content, err := m.logWriter.GetCurrentLogContent(serverName); err != nil {
+			log.Warnf("Subscribe: failed to replay current log for %s: %v", serverName, err)
+		} else if len(content) > 0 {
+			if len(content) > sub.maxBytes {
+				content = content[len(content)-sub.maxBytes:]
+			}
+			sub.enqueue(content)
+		}
+	}
 	session.subMu.Unlock()
+	metricSubscribers.WithLabelValues(serverName).Inc()
 
 	unsubscribe := func() {
+		cancel()
+		sub.stop()
 		session.subMu.Lock()
-		delete(session.subscribers, ch)
+		delete(session.subscribers, sub)
 		session.subMu.Unlock()
+		metricSubscribers.WithLabelValues(serverName).Dec()
 	}
 
-	return ch, unsubscribe
+	return sub.out, unsubscribe, nil
+}
+
+// publish fans out console data to every subscriber of this session. Each
+// subscriber applies its own OnLag policy rather than this call blocking or
+// silently dropping data on the session's behalf.
+func (s *Session) publish(data []byte) {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+
+	for sub := range s.subscribers {
+		sub.enqueue(data)
+	}
 }
 
 func (m *Manager) runSession(ctx context.Context, session *Session) {
 	backoff := time.Second
+	firstAttempt := true
 
 	for {
 		select {
@@ -146,6 +232,11 @@ func (m *Manager) runSession(ctx context.Context, session *Session) {
 		default:
 		}
 
+		if !firstAttempt {
+			metricReconnects.WithLabelValues(session.ServerName).Inc()
+		}
+		firstAttempt = false
+
 		log.Infof("Connecting SOL to %s (%s)", session.ServerName, session.IP)
 
 		err := m.connectSOL(ctx, session)
@@ -169,9 +260,69 @@ func (m *Manager) runSession(ctx context.Context, session *Session) {
 }
 
 func (m *Manager) connectSOL(ctx context.Context, session *Session) error {
-	// SOL disabled - TTY handling on arm64 container causes hangs
-	// TODO: Implement native IPMI SOL using goipmi library
-	session.Connected = false
-	session.LastError = "SOL disabled - arm64 TTY issues"
-	return fmt.Errorf("SOL disabled")
+	m.mu.RLock()
+	backendType := m.backends[session.ServerName]
+	username, password := m.username, m.password
+	m.mu.RUnlock()
+
+	backend, err := NewBackend(backendType)
+	if err != nil {
+		return err
+	}
+
+	stream, err := backend.Connect(ctx, BackendConfig{
+		ServerName: session.ServerName,
+		IP:         session.IP,
+		Username:   username,
+		Password:   password,
+	})
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	session.Connected = true
+	session.LastError = ""
+	metricConnected.WithLabelValues(session.ServerName).Set(1)
+	defer metricConnected.WithLabelValues(session.ServerName).Set(0)
+	log.Infof("SOL connected to %s (%s)", session.ServerName, session.IP)
+
+	buf := make([]byte, 4096)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		n, err := stream.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+
+			metricBytesWritten.WithLabelValues(session.ServerName).Add(float64(n))
+
+			if m.logWriter != nil {
+				if werr := m.logWriter.Write(session.ServerName, data); werr != nil {
+					log.Warnf("Failed to write console log for %s: %v", session.ServerName, werr)
+				}
+			}
+			if m.rebootDetector != nil {
+				if event := m.rebootDetector.Check(session.ServerName, string(data)); event != nil {
+					log.Infof("Reboot detected for %s: cause=%s trigger=%q", event.ServerName, event.ClassifiedCause, event.TriggerPattern)
+					metricReboots.WithLabelValues(event.ServerName, string(event.ClassifiedCause)).Inc()
+					m.analytics.RecordReboot(*event)
+					m.eventBus.Publish(*event)
+				}
+			}
+			session.publish(data)
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
 }
@@ -0,0 +1,79 @@
+package sol
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricConnected = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "console_sol_connected",
+		Help: "Whether the SOL session for a server is currently connected (1) or not (0).",
+	}, []string{"server"})
+
+	metricReconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "console_sol_reconnects_total",
+		Help: "Number of times a SOL session has had to reconnect.",
+	}, []string{"server"})
+
+	metricBytesWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "console_sol_bytes_written_total",
+		Help: "Total console bytes received over SOL and written to the log.",
+	}, []string{"server"})
+
+	metricReboots = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "console_reboots_total",
+		Help: "Number of reboots detected, by classified cause.",
+	}, []string{"server", "cause"})
+
+	metricBootDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "console_boot_duration_seconds",
+		Help:    "Time from a detected reboot to the OS coming back up, measured from RebootDetector state transitions.",
+		Buckets: prometheus.ExponentialBuckets(5, 2, 10), // 5s .. ~42m
+	}, []string{"server"})
+
+	// console_bios_dwell_seconds currently tracks the same BIOS/POST -> OS
+	// transition as console_boot_duration_seconds, since RebootDetector only
+	// distinguishes two states (BIOS, OS). It's kept separate so a future
+	// finer-grained detector (e.g. POST vs. bootloader vs. kernel init) can
+	// report it independently without a metric rename.
+	metricBIOSDwell = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "console_bios_dwell_seconds",
+		Help:    "Time spent showing BIOS/POST output before the OS takes over.",
+		Buckets: prometheus.ExponentialBuckets(5, 2, 10),
+	}, []string{"server"})
+
+	metricSubscribers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "console_sol_subscribers",
+		Help: "Number of active subscribers to a server's SOL session.",
+	}, []string{"server"})
+
+	metricSubscriberDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "console_subscriber_dropped_total",
+		Help: "Number of subscribers evicted for lagging behind (OnLagDrop).",
+	}, []string{"server"})
+)
+
+// RegisterHTTPHandlers mounts /metrics and /api/analytics/{server} on mux,
+// wired to this Manager's state.
+func (m *Manager) RegisterHTTPHandlers(mux *http.ServeMux) {
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/api/analytics/", m.handleAnalytics)
+}
+
+func (m *Manager) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	serverName := strings.TrimPrefix(r.URL.Path, "/api/analytics/")
+	if serverName == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.GetAllAnalytics())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m.GetAnalytics(serverName))
+}
@@ -7,17 +7,60 @@ import (
 	"time"
 )
 
+const recentLinesWindow = 50
+
 type serverState struct {
-	inOS       bool      // true if we've seen OS output
-	lastReboot time.Time // last time we detected a reboot
+	inOS              bool      // true if we've seen OS output
+	lastReboot        time.Time // last time we detected a reboot
+	osStartedAt       time.Time // when we most recently transitioned into inOS
+	recentLines       []string  // bounded scrollback used to classify the cause of a reboot
+	lastBootDwellFrom time.Time // the lastReboot value we've already reported a boot-dwell sample for
+}
+
+// RebootCause classifies why a server rebooted, inferred from console text
+// seen shortly before the BIOS/POST transition.
+type RebootCause string
+
+const (
+	CausePowerCycle RebootCause = "power_cycle"
+	CauseWarmReboot RebootCause = "warm_reboot"
+	CausePanic      RebootCause = "panic"
+	CauseOOM        RebootCause = "oom"
+	CauseWatchdog   RebootCause = "watchdog"
+	CauseUnknown    RebootCause = "unknown"
+)
+
+// RebootEvent describes a single detected OS -> BIOS transition.
+type RebootEvent struct {
+	ServerName      string
+	DetectedAt      time.Time
+	TriggerPattern  string
+	MatchedText     string
+	PrevUptime      time.Duration
+	ClassifiedCause RebootCause
 }
 
 type RebootDetector struct {
-	biosPatterns []*regexp.Regexp
-	osPatterns   []*regexp.Regexp
-	states       map[string]*serverState
-	cooldown     time.Duration
-	mu           sync.Mutex
+	biosPatterns       []*regexp.Regexp
+	osPatterns         []*regexp.Regexp
+	panicPatterns      []*regexp.Regexp
+	oomPatterns        []*regexp.Regexp
+	watchdogPatterns   []*regexp.Regexp
+	warmRebootPatterns []*regexp.Regexp
+	states             map[string]*serverState
+	cooldown           time.Duration
+	mu                 sync.Mutex
+
+	onBootComplete func(serverName string, dwell time.Duration)
+}
+
+// OnBootComplete registers a callback invoked whenever a server's console
+// returns to showing OS output after a detected reboot, with the dwell time
+// between the reboot and the OS coming back.
+func (rd *RebootDetector) OnBootComplete(fn func(serverName string, dwell time.Duration)) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	rd.onBootComplete = fn
 }
 
 func NewRebootDetector(patterns []string) *RebootDetector {
@@ -79,10 +122,29 @@ func NewRebootDetector(patterns []string) *RebootDetector {
 		}
 	}
 
+	// Cause-classification patterns, checked against recent scrollback once a
+	// reboot has been detected.
+	rd.panicPatterns = compilePatterns(`Kernel panic`, `Oops`, `BUG:`, `Call Trace:`)
+	rd.oomPatterns = compilePatterns(`Out of memory: Kill process`)
+	rd.watchdogPatterns = compilePatterns(`watchdog: BUG: soft lockup`)
+	rd.warmRebootPatterns = compilePatterns(`Restarting system`, `reboot: Restarting`, `shutdown -r`, `systemctl reboot`)
+
 	return rd
 }
 
-func (rd *RebootDetector) Check(serverName, text string) bool {
+func compilePatterns(patterns ...string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if re, err := regexp.Compile("(?i)" + p); err == nil {
+			compiled = append(compiled, re)
+		}
+	}
+	return compiled
+}
+
+// Check feeds a chunk of console text through the detector and returns a
+// RebootEvent if it completes an OS -> BIOS transition, or nil otherwise.
+func (rd *RebootDetector) Check(serverName, text string) *RebootEvent {
 	rd.mu.Lock()
 	defer rd.mu.Unlock()
 
@@ -92,40 +154,112 @@ func (rd *RebootDetector) Check(serverName, text string) bool {
 		rd.states[serverName] = state
 	}
 
+	state.recentLines = append(state.recentLines, text)
+	if len(state.recentLines) > recentLinesWindow {
+		state.recentLines = state.recentLines[len(state.recentLines)-recentLinesWindow:]
+	}
+
 	// Check cooldown
 	if time.Since(state.lastReboot) < rd.cooldown {
 		// Still in cooldown, but update OS state if we see OS patterns
 		if rd.matchesOS(text) {
-			state.inOS = true
+			rd.markInOS(serverName, state)
 		}
-		return false
+		return nil
 	}
 
 	// Check if we see OS patterns - mark that OS is running
 	if rd.matchesOS(text) {
-		state.inOS = true
-		return false
+		rd.markInOS(serverName, state)
+		return nil
 	}
 
 	// Check if we see BIOS patterns
-	if rd.matchesBIOS(text) {
+	if pattern, matched := rd.matchBIOS(text); matched {
 		// Only trigger if we were previously in OS state
 		// This means we transitioned from OS -> BIOS = reboot
 		if state.inOS {
+			now := time.Now()
+			event := &RebootEvent{
+				ServerName:      serverName,
+				DetectedAt:      now,
+				TriggerPattern:  pattern,
+				MatchedText:     text,
+				ClassifiedCause: rd.classifyCause(state.recentLines),
+			}
+			if !state.osStartedAt.IsZero() {
+				event.PrevUptime = now.Sub(state.osStartedAt)
+			}
+
 			state.inOS = false
-			state.lastReboot = time.Now()
-			return true
+			state.lastReboot = now
+			state.recentLines = nil
+			return event
 		}
 		// If we weren't in OS state, we're still in boot process
 		// Don't trigger another rotation
-		return false
+		return nil
 	}
 
-	return false
+	return nil
 }
 
-func (rd *RebootDetector) matchesBIOS(text string) bool {
-	for _, p := range rd.biosPatterns {
+// markInOS records that a server's console is showing OS output. If this is
+// a fresh transition after a detected reboot, it reports the boot dwell time
+// (BIOS/POST seen -> OS seen again) to the registered boot-complete handler.
+func (rd *RebootDetector) markInOS(serverName string, state *serverState) {
+	now := time.Now()
+	if !state.inOS {
+		state.osStartedAt = now
+		if !state.lastReboot.IsZero() && state.lastReboot.After(state.lastBootDwellFrom) {
+			dwell := now.Sub(state.lastReboot)
+			state.lastBootDwellFrom = state.lastReboot
+			if rd.onBootComplete != nil {
+				rd.onBootComplete(serverName, dwell)
+			}
+		}
+	}
+	state.inOS = true
+}
+
+// classifyCause inspects the scrollback leading up to a detected reboot and
+// tags its likely cause. Kernel distress signatures take priority since
+// they're the most actionable; otherwise we fall back to distinguishing a
+// graceful/warm reboot from an unannounced power cycle.
+func (rd *RebootDetector) classifyCause(recentLines []string) RebootCause {
+	// Watchdog before panic: a soft lockup line also matches the panic
+	// pattern `BUG:`.
+	for _, line := range recentLines {
+		if matchesAny(rd.watchdogPatterns, line) {
+			return CauseWatchdog
+		}
+	}
+	for _, line := range recentLines {
+		if matchesAny(rd.panicPatterns, line) {
+			return CausePanic
+		}
+	}
+	for _, line := range recentLines {
+		if matchesAny(rd.oomPatterns, line) {
+			return CauseOOM
+		}
+	}
+	for _, line := range recentLines {
+		if matchesAny(rd.warmRebootPatterns, line) {
+			return CauseWarmReboot
+		}
+	}
+	// No graceful-shutdown or distress signature seen before the BIOS/POST
+	// banner reappeared: most likely an unannounced power cycle, but be
+	// conservative since scrollback is bounded and may have missed it.
+	if len(recentLines) >= recentLinesWindow {
+		return CausePowerCycle
+	}
+	return CauseUnknown
+}
+
+func matchesAny(patterns []*regexp.Regexp, text string) bool {
+	for _, p := range patterns {
 		if p.MatchString(text) {
 			return true
 		}
@@ -133,6 +267,17 @@ func (rd *RebootDetector) matchesBIOS(text string) bool {
 	return false
 }
 
+// matchBIOS reports whether text matches a BIOS/POST pattern, returning the
+// source of the match for TriggerPattern.
+func (rd *RebootDetector) matchBIOS(text string) (string, bool) {
+	for _, p := range rd.biosPatterns {
+		if p.MatchString(text) {
+			return p.String(), true
+		}
+	}
+	return "", false
+}
+
 func (rd *RebootDetector) matchesOS(text string) bool {
 	// Also check for common OS indicators without regex
 	lowerText := strings.ToLower(text)
@@ -174,5 +319,5 @@ func (rd *RebootDetector) MarkOSRunning(serverName string) {
 		state = &serverState{}
 		rd.states[serverName] = state
 	}
-	state.inOS = true
+	rd.markInOS(serverName, state)
 }
@@ -0,0 +1,204 @@
+package sol
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LagPolicy controls what happens when a subscriber falls behind the rate
+// console data is published at, i.e. its buffer would exceed BufferBytes.
+type LagPolicy string
+
+const (
+	// OnLagDrop evicts the subscriber: it receives a final {"error":"lagged"}
+	// sentinel message and its channel is then closed. This is the default.
+	OnLagDrop LagPolicy = "drop"
+	// OnLagCoalesce discards the oldest buffered bytes to make room for new
+	// data, so the subscriber always sees the most recent output but may
+	// miss a gap in the middle.
+	OnLagCoalesce LagPolicy = "coalesce"
+	// OnLagBlock applies backpressure to the publisher itself: console
+	// ingestion for the whole session waits for this subscriber to drain.
+	// Only appropriate for a single trusted, reliable consumer (e.g. the log
+	// writer), since one slow OnLagBlock subscriber stalls every other
+	// subscriber of the same session too.
+	OnLagBlock LagPolicy = "block"
+)
+
+const defaultSubscriberBufferBytes = 256 * 1024
+
+// laggedSentinel is the final message sent to a subscriber dropped for
+// lagging, before its channel is closed, so a WebSocket handler can tell the
+// client it missed data rather than silently going quiet.
+var laggedSentinel, _ = json.Marshal(map[string]string{"error": "lagged"})
+
+// SubscribeOptions controls how a subscriber's channel is fed.
+type SubscribeOptions struct {
+	// BufferBytes bounds how much unread console data is held for this
+	// subscriber before OnLag kicks in. Defaults to 256KB.
+	BufferBytes int
+	// OnLag selects the behavior when the buffer would overflow. Defaults to
+	// OnLagDrop.
+	OnLag LagPolicy
+	// ReplayFromCurrentLog, if true, atomically prepends the decompressed
+	// tail of the server's current log segment before any live bytes, so a
+	// viewer that just attached doesn't miss data written between reading
+	// the log and subscribing.
+	ReplayFromCurrentLog bool
+}
+
+// subscriber owns a bounded ring buffer fed by Session.publish and drained by
+// a dedicated goroutine that writes to the caller's output channel. This is
+// what keeps one slow consumer from stalling delivery to every other
+// subscriber of the same session.
+type subscriber struct {
+	serverName string
+	out        chan []byte
+	onLag      LagPolicy
+	maxBytes   int
+
+	mu       sync.Mutex
+	buf      [][]byte
+	bufBytes int
+	stopped  bool
+	lagged   bool
+	wake     chan struct{}
+}
+
+func newSubscriber(serverName string, opts SubscribeOptions) *subscriber {
+	maxBytes := opts.BufferBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultSubscriberBufferBytes
+	}
+	onLag := opts.OnLag
+	if onLag == "" {
+		onLag = OnLagDrop
+	}
+	return &subscriber{
+		serverName: serverName,
+		out:        make(chan []byte, 1),
+		onLag:      onLag,
+		maxBytes:   maxBytes,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// enqueue hands a chunk of console data to the subscriber. It never blocks
+// the caller unless onLag is OnLagBlock, in which case it waits for the
+// subscriber's drain loop to free up room rather than dropping or evicting.
+func (s *subscriber) enqueue(data []byte) {
+	for {
+		s.mu.Lock()
+		if s.stopped || s.lagged {
+			s.mu.Unlock()
+			return
+		}
+
+		if s.bufBytes+len(data) <= s.maxBytes {
+			s.buf = append(s.buf, data)
+			s.bufBytes += len(data)
+			s.mu.Unlock()
+			s.signal()
+			return
+		}
+
+		switch s.onLag {
+		case OnLagCoalesce:
+			for s.bufBytes+len(data) > s.maxBytes && len(s.buf) > 0 {
+				s.bufBytes -= len(s.buf[0])
+				s.buf = s.buf[1:]
+			}
+			s.buf = append(s.buf, data)
+			s.bufBytes += len(data)
+			s.mu.Unlock()
+			s.signal()
+			return
+
+		case OnLagBlock:
+			s.mu.Unlock()
+			<-s.wake // retry once the drain loop has freed some room
+			continue
+
+		default: // OnLagDrop
+			s.lagged = true
+			s.mu.Unlock()
+			metricSubscriberDropped.WithLabelValues(s.serverName).Inc()
+			log.Warnf("Subscriber for %s lagged past %d bytes and was dropped", s.serverName, s.maxBytes)
+			s.signal()
+			return
+		}
+	}
+}
+
+func (s *subscriber) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run drains the buffer to the subscriber's output channel until it is
+// stopped, lagged and empty, or ctx is done. It must run in its own
+// goroutine, started before the subscriber is registered with a session.
+func (s *subscriber) run(ctx context.Context) {
+	defer close(s.out)
+	// However run() exits - ctx done, drained+lagged, or drained+stopped -
+	// make sure stopped ends up true and wake is signaled, so a producer
+	// parked in enqueue's OnLagBlock retry loop is always released instead
+	// of blocking forever (e.g. if ctx is cancelled without the caller ever
+	// invoking the returned unsubscribe function).
+	defer s.stop()
+
+	for {
+		s.mu.Lock()
+		var chunk []byte
+		if len(s.buf) > 0 {
+			chunk = s.buf[0]
+			s.buf = s.buf[1:]
+			s.bufBytes -= len(chunk)
+		}
+		drained := len(s.buf) == 0 && chunk == nil
+		lagged := s.lagged
+		stopped := s.stopped
+		s.mu.Unlock()
+
+		if chunk != nil {
+			select {
+			case s.out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+			s.signal() // a blocked OnLagBlock enqueue may now have room
+			continue
+		}
+
+		if drained && lagged {
+			select {
+			case s.out <- laggedSentinel:
+			case <-ctx.Done():
+			}
+			return
+		}
+		if drained && stopped {
+			return
+		}
+
+		select {
+		case <-s.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// stop requests that the subscriber drain whatever is buffered and then
+// exit, without sending a lagged sentinel.
+func (s *subscriber) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.signal()
+}